@@ -7,67 +7,28 @@ import (
 	"os"
 	"time"
 
+	"github.com/coreweave/virtual-server/pkg/imagecache"
+	"github.com/coreweave/virtual-server/pkg/validation"
+	"github.com/coreweave/virtual-server/pkg/vsclient"
+
 	vsv1alpha "github.com/coreweave/virtual-server/api/v1alpha1"
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
 	"kubevirt.io/client-go/kubecli"
 )
 
-type ReadyResponse string
-
-const (
-	// VSReady indicates that the VirtualServer is ready
-	VSReady ReadyResponse = "Ready"
-	// VSStopped indicates that the VirtualServer is stopped
-	VSStopped ReadyResponse = "Stopped"
-	// VSUnknown indicates that the VirtualServer has unknown state or unacceptable by the Ready function
-	VSUnknown ReadyResponse = "Unknown"
-)
-
-// Ready waits until Virtual Server reach expected status
-func Ready(namespace, name string, c client.Client) ReadyResponse {
-	for {
-		vs := &vsv1alpha.VirtualServer{}
-		err := c.Get(context.Background(), client.ObjectKey{
-			Namespace: namespace,
-			Name:      name,
-		}, vs)
-
-		// VirtualServer has yet to receive any status
-		if err != nil {
-			continue
-		}
-
-		cond := vs.GetReadyStatus()
-		if cond == nil {
-			return VSUnknown
-		} else if cond.Reason == string(vsv1alpha.VSConditionReasonReady) &&
-			cond.Type == string(vsv1alpha.VSConditionTypeReady) &&
-			cond.Status == "True" {
-			fmt.Printf("Network:\n\tinternalIP: %s\n\texternal IP: %s\n\tfloating IPs:",
-				vs.Status.InternalIP(),
-				vs.Status.ExternalIP(),
-			)
-			for service, ip := range vs.Status.FloatingIPs() {
-				fmt.Printf("\t\t%s: %s\n", service, ip)
-			}
-			fmt.Printf("\n")
-			return VSReady
-		} else if cond.Reason == string(vsv1alpha.VSConditionReasonStopped) &&
-			cond.Type == string(vsv1alpha.VSConditionTypeReady) &&
-			cond.Status == "False" {
-			return VSStopped
-		}
-		time.Sleep(2)
-	}
-}
+// smartCloneStorageClasses lists the StorageClasses this cluster's CSI
+// driver is known to support smart-clone (CreateVolume-from-volume) on.
+// Root disks requesting any other StorageClass fall back to a full import
+// of the source instead.
+var smartCloneStorageClasses = []string{"ceph-ssd-2-replica"}
 
 func main() {
 	name := "my-test-virtual-server"
@@ -78,7 +39,8 @@ func main() {
 		namespace = "default"
 	}
 	// Uses the value of the KUBECONFIG environment variable as a filepath to a kube config file
-	c, err := client.New(config.GetConfigOrDie(), client.Options{})
+	restConfig := config.GetConfigOrDie()
+	c, err := client.NewWithWatch(restConfig, client.Options{})
 	if err != nil {
 		log.Fatalf("Failed to create client\n")
 	}
@@ -86,11 +48,12 @@ func main() {
 	username, usernameExist := os.LookupEnv("USERNAME")
 	password, passwordExist := os.LookupEnv("PASSWORD")
 
-	if !usernameExist || passwordExist {
+	if !usernameExist || !passwordExist {
 		log.Fatalf("Required environment variables USERNAME and PASSWORD not found")
 	}
 
 	vsv1alpha.AddToScheme(c.Scheme())
+	cdiv1beta1.AddToScheme(c.Scheme())
 
 	// prepare config for kubevirt client, you need to set env variable, KUBECONFIG=<path-to-kubeconfig>/.kubeconfig
 	kubevirtClientConfig := kubecli.DefaultClientConfig(&pflag.FlagSet{})
@@ -101,6 +64,18 @@ func main() {
 		log.Fatalf("Cannot obtain KubeVirt client: %v\n", err)
 	}
 
+	vsClient := vsclient.New(c, kubevirtClient)
+
+	if err := run(c, vsClient, name, namespace, username, password); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// run builds and submits the example VirtualServer. It tears down the
+// example PVC and floating IP Service it creates along the way whenever it
+// returns, whether or not a later step failed, so a pre-flight validation
+// or submission error doesn't leak them.
+func run(c client.WithWatch, vsClient *vsclient.Client, name, namespace, username, password string) error {
 	// Create a new VirtualServer with the name "my-virtual-server" to be deployed in the "default" namespace
 	virtualServer := vsv1alpha.NewVirtualServer(name, namespace)
 
@@ -131,16 +106,46 @@ func main() {
 		Password: password,
 	})
 
-	// Configure the root filesystem of the VirtualServer to clone a preexisting PVC namedubuntu1804-docker-master-20210210-ord1
-	// sourced in the vd-images namespace
-	err = virtualServer.ConfigureStorageRootWithPVCSource(vsv1alpha.VirtualServerStorageRootPVCSource{
+	// Configure the root filesystem of the VirtualServer by importing a CDI
+	// DataVolume rather than requiring the PVC to already exist. cacheEntry
+	// describes the copy of this image already imported into the shared
+	// vd-images namespace: when the target StorageClass is known to support
+	// smart-clone, the DataVolume clones that copy directly instead of
+	// re-downloading it from its origin on every VirtualServer creation.
+	rootStorageClassName := "ceph-ssd-2-replica"
+	cacheEntry := imagecache.Entry{
+		Key:       "ubuntu1804-docker-master-20210210-ord1",
+		Namespace: imagecache.Namespace,
+		PVCName:   "ubuntu1804-docker-master-20210210-ord1",
+	}
+	dataVolumeSource := vsv1alpha.DataVolumeSource{
+		HTTP: &vsv1alpha.DataVolumeSourceHTTP{
+			URL: "https://images.example.com/ubuntu1804-docker-master-20210210-ord1.qcow2",
+		},
+	}
+	if imagecache.SupportsSmartClone(rootStorageClassName, smartCloneStorageClasses) {
+		dataVolumeSource = vsv1alpha.DataVolumeSource{
+			PVC: &vsv1alpha.DataVolumeSourcePVC{
+				Name:      cacheEntry.PVCName,
+				Namespace: cacheEntry.Namespace,
+			},
+		}
+	}
+
+	err := virtualServer.ConfigureStorageRootWithDataVolumeSource(vsv1alpha.VirtualServerStorageRootDataVolumeSource{
+		Name:             imagecache.CloneSourceName(name, cacheEntry),
 		Size:             "40Gi",
-		PVCName:          "ubuntu1804-docker-master-20210210-ord1",
-		PVCNamespace:     "vd-images",
-		StorageClassName: "ceph-ssd-2-replica",
+		StorageClassName: rootStorageClassName,
+		VolumeMode:       corev1.PersistentVolumeFilesystem,
+		AccessMode:       corev1.ReadWriteOnce,
+		Source:           dataVolumeSource,
+		Annotations: map[string]string{
+			imagecache.AnnotationCacheKey:       cacheEntry.Key,
+			imagecache.AnnotationCacheNamespace: cacheEntry.Namespace,
+		},
 	})
 	if err != nil {
-		log.Fatalf("Cound not configure root filesystem\n")
+		return fmt.Errorf("could not configure root filesystem: %w", err)
 	}
 	// Add a floating IP to the VirtualServer
 	//virtualServer.AddFloatingIP("my-floating-ip-service")
@@ -159,54 +164,82 @@ func main() {
 	// Set the VirtualServer to start as soon as it is created
 	virtualServer.InitializeRunning(true)
 
-	// Create an example pvc to be added as an additional file system
+	// Create an example pvc to be added as an additional file system. It is
+	// deleted when run returns, so a bad VirtualServer submission doesn't
+	// leak it.
 	pvc := buildPVC("example-pvc", namespace, resource.MustParse("256Gi"))
 	if err := c.Create(context.Background(), pvc); err != nil {
-		log.Fatalf("Could not create example pvc\nReason: %s", err.Error())
+		return fmt.Errorf("could not create example pvc: %w", err)
 	}
+	defer func() {
+		if err := client.IgnoreNotFound(c.Delete(context.Background(), pvc)); err != nil {
+			log.Printf("Could not clean up example pvc %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		}
+	}()
 
 	// Add the example PVC as a file system to the Virtual Server
 	virtualServer.AddPVCFileSystem("example-storage", pvc.Name, false)
 
+	// Likewise deleted when run returns.
 	service := buildFloatingIPService("example-floating-ip-service", namespace)
 	if err := c.Create(context.Background(), service); err != nil {
-		log.Fatalf("Could not create example floatingIP service\nReason: %s", err.Error())
+		return fmt.Errorf("could not create example floatingIP service: %w", err)
 	}
+	defer func() {
+		if err := client.IgnoreNotFound(c.Delete(context.Background(), service)); err != nil {
+			log.Printf("Could not clean up example floatingIP service %s/%s: %v", service.Namespace, service.Name, err)
+		}
+	}()
 
 	// Add the example floatingIP service to the VirtualServer
 	virtualServer.AddFloatingIP(service.Name)
 
-	// Delete Virtual Server if already exists
-	err = c.Delete(context.Background(), virtualServer)
-	if err != nil {
-		if apierrors.IsNotFound(err) == true {
-			fmt.Printf("VirtualServer %s in namespace %s already deleted\n", name, namespace)
-		} else {
-			log.Fatalf("Failed to create VirtualServer\nReason: %s", err.Error())
+	// Catch misconfigurations before submitting to the API server.
+	if errs := validation.Validate(context.Background(), c, virtualServer); len(errs) > 0 {
+		for _, verr := range errs {
+			log.Printf("VirtualServer validation error: %v", verr)
 		}
+		return fmt.Errorf("VirtualServer failed pre-flight validation")
 	}
 
-	// Create a new Virtual Server
-	err = c.Create(context.Background(), virtualServer)
-	if err != nil {
-		log.Fatalf("Failed to create VirtualServer\nReason: %s", err.Error())
+	// Delete Virtual Server if already exists
+	if err := vsClient.Delete(context.Background(), namespace, name); err != nil {
+		return fmt.Errorf("failed to delete existing VirtualServer: %w", err)
 	}
 
-	// Wait until Virtual Server is ready
-	fmt.Printf("VirtualServer status: %s\n", Ready(namespace, name, c))
+	// Create a new Virtual Server
+	if err := vsClient.Create(context.Background(), virtualServer); err != nil {
+		return fmt.Errorf("failed to create VirtualServer: %w", err)
+	}
 
-	err = kubevirtClient.VirtualMachine(namespace).Stop(name)
+	// Wait until Virtual Server is ready, or give up after five minutes.
+	ready, err := vsClient.WaitReady(context.Background(), namespace, name, vsclient.WithTimeout(5*time.Minute))
 	if err != nil {
-		log.Fatalf("Cannot stop virtual sever %s in namespace %s, err: %v\n", name, namespace, err)
+		return fmt.Errorf("VirtualServer did not become ready: %w", err)
+	}
+	fmt.Printf("Network:\n\tinternalIP: %s\n\texternal IP: %s\n\tfloating IPs:",
+		ready.Status.InternalIP(),
+		ready.Status.ExternalIP(),
+	)
+	for service, ip := range ready.Status.FloatingIPs() {
+		fmt.Printf("\t\t%s: %s\n", service, ip)
+	}
+	fmt.Printf("\n")
+
+	if err := vsClient.Stop(namespace, name); err != nil {
+		return fmt.Errorf("cannot stop virtual sever %s in namespace %s: %w", name, namespace, err)
 	}
 
 	// Wait until Virtual Server is stopped
-	fmt.Printf("VirtualServer status: %s\n", Ready(namespace, name, c))
+	if _, err := vsClient.WaitStopped(context.Background(), namespace, name, vsclient.WithTimeout(5*time.Minute)); err != nil {
+		return fmt.Errorf("VirtualServer did not stop: %w", err)
+	}
+	fmt.Printf("VirtualServer status: %s\n", vsclient.StatusStopped)
 
-	err = c.Delete(context.Background(), virtualServer)
-	if err != nil {
-		log.Fatalf("Failed to delete VirtualServer\nReason: %s", err.Error())
+	if err := vsClient.Delete(context.Background(), namespace, name); err != nil {
+		return fmt.Errorf("failed to delete VirtualServer: %w", err)
 	}
+	return nil
 }
 
 func buildPVC(name string, namespace string, size resource.Quantity) *corev1.PersistentVolumeClaim {
@@ -0,0 +1,69 @@
+// Package imagecache implements the "standard image cache" convention for
+// VirtualServer root disks provisioned via a CDI DataVolume.
+//
+// Rather than having every tenant import the same base image from its
+// origin (HTTP/registry/S3) on every VirtualServer creation, an image is
+// imported once into a shared namespace and subsequent VirtualServers clone
+// it with CDI smart-clone (a CSI VolumeSnapshot-backed clone) whenever the
+// target StorageClass supports it. This mirrors the import pattern used by
+// other KubeVirt/CDI consumers and avoids re-downloading the same image
+// for every tenant.
+package imagecache
+
+import (
+	"fmt"
+)
+
+const (
+	// Namespace is the default namespace that cached standard images are
+	// imported into and cloned from.
+	Namespace = "vd-images"
+
+	// AnnotationCacheKey, when set on a VirtualServerStorageRootDataVolumeSource
+	// annotation, identifies the cache entry (source URL or image name) that
+	// the generated DataVolume should be imported into or smart-cloned from.
+	AnnotationCacheKey = "virtualservers.coreweave.com/image-cache-key"
+
+	// AnnotationCacheNamespace overrides Namespace for deployments that keep
+	// their shared image cache somewhere other than "vd-images" (e.g.
+	// "kubevirt-images").
+	AnnotationCacheNamespace = "virtualservers.coreweave.com/image-cache-namespace"
+)
+
+// Entry describes a single standard image that has already been imported
+// into the shared cache namespace and is available to be smart-cloned into
+// tenant namespaces.
+type Entry struct {
+	// Key identifies the cache entry. By convention this is a stable slug
+	// derived from the source URL or image name, e.g. "ubuntu2204-docker-master".
+	Key string
+	// Namespace is the namespace the backing PVC/DataVolume lives in.
+	Namespace string
+	// PVCName is the name of the imported PVC backing this cache entry.
+	PVCName string
+}
+
+// CloneSourceName returns the per-tenant DataVolume name that should be used
+// when cloning a cache Entry, namespaced by the requesting VirtualServer so
+// repeat creations are idempotent.
+func CloneSourceName(vsName string, entry Entry) string {
+	return fmt.Sprintf("%s-%s-root", vsName, entry.Key)
+}
+
+// SupportsSmartClone reports whether the given StorageClass is known to
+// support CSI smart-clone (same-storage-class clone via VolumeSnapshot or
+// CSI CreateVolume-from-volume). Callers fall back to a full HTTP/registry
+// import when it does not.
+//
+// This is a static allow-list rather than a live capability probe because
+// the CSI CREATE_DELETE_SNAPSHOT/CLONE_VOLUME capability isn't exposed to
+// clients without a privileged CSIDriver lookup; operators extend it to
+// match the storage classes available in their cluster.
+func SupportsSmartClone(storageClassName string, smartCloneClasses []string) bool {
+	for _, sc := range smartCloneClasses {
+		if sc == storageClassName {
+			return true
+		}
+	}
+	return false
+}
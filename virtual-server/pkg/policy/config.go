@@ -0,0 +1,40 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// EnvStoragePolicyFile is the environment variable, modeled on the
+// INFRA_STORAGE_CLASS_ENFORCEMENT convention, that names a YAML or JSON
+// file containing a StoragePolicy document. It is consulted by
+// LoadFromEnv when no explicit path is given.
+const EnvStoragePolicyFile = "INFRA_STORAGE_CLASS_ENFORCEMENT_POLICY_FILE"
+
+// LoadFromFile reads and parses a StoragePolicy document (YAML or JSON,
+// sigs.k8s.io/yaml accepts both) from path.
+func LoadFromFile(path string) (StoragePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return StoragePolicy{}, fmt.Errorf("read storage policy file %s: %w", path, err)
+	}
+	var p StoragePolicy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return StoragePolicy{}, fmt.Errorf("parse storage policy file %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// LoadFromEnv loads a StoragePolicy from the file named by
+// EnvStoragePolicyFile. It returns the zero StoragePolicy, which allows
+// no PVC source at all, with no error if the environment variable is
+// unset.
+func LoadFromEnv() (StoragePolicy, error) {
+	path, ok := os.LookupEnv(EnvStoragePolicyFile)
+	if !ok {
+		return StoragePolicy{}, nil
+	}
+	return LoadFromFile(path)
+}
@@ -0,0 +1,147 @@
+// Package policy gates which source PVCs a VirtualServer root disk is
+// allowed to clone from, so tenants cannot point ConfigureStorageRootWithPVCSource
+// at arbitrary infra namespaces. This mirrors the infraClusterLabels
+// restriction pattern used by kubevirt-csi-driver.
+package policy
+
+import (
+	"fmt"
+
+	vsv1alpha "github.com/coreweave/virtual-server/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ViolatedRule identifies which policy check rejected a PVC source.
+type ViolatedRule string
+
+const (
+	RuleNamespaceNotAllowed     ViolatedRule = "NamespaceNotAllowed"
+	RuleStorageClassNotAllowed  ViolatedRule = "StorageClassNotAllowed"
+	RuleLabelSelectorNotMatched ViolatedRule = "LabelSelectorNotMatched"
+)
+
+// Violation is returned when a requested PVC source does not satisfy the
+// configured StoragePolicy.
+type Violation struct {
+	Rule    ViolatedRule
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("storage policy violation (%s): %s", v.Rule, v.Message)
+}
+
+// StoragePolicy gates root-disk PVC sources by namespace, StorageClass,
+// and a required label selector the source PVC must match.
+type StoragePolicy struct {
+	// AllowedNamespaces lists the namespaces root disks may clone a source
+	// PVC from. A nil or empty list allows no namespaces.
+	AllowedNamespaces []string `json:"allowedNamespaces" yaml:"allowedNamespaces"`
+	// AllowedStorageClasses lists the StorageClassName values root disks
+	// may request. A nil or empty list allows no storage classes.
+	AllowedStorageClasses []string `json:"allowedStorageClasses" yaml:"allowedStorageClasses"`
+	// RequiredSourceLabels, if set, must all be present on the source PVC
+	// for the clone to be permitted.
+	RequiredSourceLabels map[string]string `json:"requiredSourceLabels" yaml:"requiredSourceLabels"`
+}
+
+// SourcePVC carries the fields of the source PVC needed to evaluate a
+// StoragePolicy. Callers populate this from the live PVC object (so the
+// label check reflects reality) rather than from the VirtualServer spec
+// alone.
+type SourcePVC struct {
+	Namespace        string
+	StorageClassName string
+	Labels           map[string]string
+}
+
+// Validate checks source against p, returning a *Violation identifying the
+// first rule that failed, or nil if source satisfies the policy.
+func (p StoragePolicy) Validate(source SourcePVC) error {
+	if !contains(p.AllowedNamespaces, source.Namespace) {
+		return &Violation{
+			Rule:    RuleNamespaceNotAllowed,
+			Message: fmt.Sprintf("namespace %q is not in the allowed source namespace list %v", source.Namespace, p.AllowedNamespaces),
+		}
+	}
+	if !contains(p.AllowedStorageClasses, source.StorageClassName) {
+		return &Violation{
+			Rule:    RuleStorageClassNotAllowed,
+			Message: fmt.Sprintf("storage class %q is not in the allowed storage class list %v", source.StorageClassName, p.AllowedStorageClasses),
+		}
+	}
+	if len(p.RequiredSourceLabels) > 0 {
+		selector := labels.SelectorFromSet(p.RequiredSourceLabels)
+		if !selector.Matches(labels.Set(source.Labels)) {
+			return &Violation{
+				Rule:    RuleLabelSelectorNotMatched,
+				Message: fmt.Sprintf("source PVC labels %v do not satisfy required selector %q", source.Labels, selector.String()),
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateVirtualServer is a convenience wrapper around Validate for the
+// common case of checking the PVC source configured on a VirtualServer
+// against the live source PVC's metadata. It covers both
+// ConfigureStorageRootWithPVCSource and ConfigureStorageRootWithDataVolumeSource
+// with a DataVolumeSourcePVC, since either one clones an existing PVC and
+// is equally subject to the namespace/storage-class/label restrictions.
+func ValidateVirtualServer(p StoragePolicy, vs *vsv1alpha.VirtualServer, sourcePVC metav1.Object) error {
+	ref, ok := SourcePVCRefOf(vs)
+	if !ok {
+		return nil
+	}
+	return p.Validate(SourcePVC{
+		Namespace:        ref.Namespace,
+		StorageClassName: ref.StorageClassName,
+		Labels:           sourcePVC.GetLabels(),
+	})
+}
+
+// SourcePVCRef identifies the PVC (if any) that a VirtualServer's root
+// disk clones from, together with the StorageClassName requested for the
+// root disk, regardless of which of the two root-disk configuration paths
+// (direct PVC clone or DataVolume with a PVC source) was used.
+type SourcePVCRef struct {
+	Namespace        string
+	Name             string
+	StorageClassName string
+}
+
+// SourcePVCRefOf extracts a SourcePVCRef from vs's root disk configuration.
+// ok is false when the root disk has no PVC source to check (e.g. it is
+// unconfigured, or its DataVolume imports from HTTP/registry/S3/blank
+// instead of cloning a PVC).
+func SourcePVCRefOf(vs *vsv1alpha.VirtualServer) (ref SourcePVCRef, ok bool) {
+	root := vs.Spec.Storage.Root
+	if root == nil {
+		return SourcePVCRef{}, false
+	}
+	if root.PVC != nil {
+		return SourcePVCRef{
+			Namespace:        root.PVC.PVCNamespace,
+			Name:             root.PVC.PVCName,
+			StorageClassName: root.PVC.StorageClassName,
+		}, true
+	}
+	if root.DataVolume != nil && root.DataVolume.Source.PVC != nil {
+		return SourcePVCRef{
+			Namespace:        root.DataVolume.Source.PVC.Namespace,
+			Name:             root.DataVolume.Source.PVC.Name,
+			StorageClassName: root.DataVolume.StorageClassName,
+		}, true
+	}
+	return SourcePVCRef{}, false
+}
+
+func contains(list []string, want string) bool {
+	for _, v := range list {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"testing"
+
+	vsv1alpha "github.com/coreweave/virtual-server/api/v1alpha1"
+)
+
+func TestStoragePolicyValidate(t *testing.T) {
+	policy := StoragePolicy{
+		AllowedNamespaces:     []string{"vd-images"},
+		AllowedStorageClasses: []string{"ceph-ssd-2-replica"},
+		RequiredSourceLabels:  map[string]string{"coreweave.com/image-cache": "true"},
+	}
+
+	tests := []struct {
+		name      string
+		source    SourcePVC
+		wantRule  ViolatedRule
+		wantError bool
+	}{
+		{
+			name: "allowed",
+			source: SourcePVC{
+				Namespace:        "vd-images",
+				StorageClassName: "ceph-ssd-2-replica",
+				Labels:           map[string]string{"coreweave.com/image-cache": "true"},
+			},
+			wantError: false,
+		},
+		{
+			name: "disallowed namespace",
+			source: SourcePVC{
+				Namespace:        "tenant-a",
+				StorageClassName: "ceph-ssd-2-replica",
+				Labels:           map[string]string{"coreweave.com/image-cache": "true"},
+			},
+			wantRule:  RuleNamespaceNotAllowed,
+			wantError: true,
+		},
+		{
+			name: "disallowed storage class",
+			source: SourcePVC{
+				Namespace:        "vd-images",
+				StorageClassName: "block-nvme-ewr1",
+				Labels:           map[string]string{"coreweave.com/image-cache": "true"},
+			},
+			wantRule:  RuleStorageClassNotAllowed,
+			wantError: true,
+		},
+		{
+			name: "missing required label",
+			source: SourcePVC{
+				Namespace:        "vd-images",
+				StorageClassName: "ceph-ssd-2-replica",
+				Labels:           map[string]string{},
+			},
+			wantRule:  RuleLabelSelectorNotMatched,
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Validate(tc.source)
+			if !tc.wantError {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want a violation of %s", tc.wantRule)
+			}
+			violation, ok := err.(*Violation)
+			if !ok {
+				t.Fatalf("Validate() error type = %T, want *Violation", err)
+			}
+			if violation.Rule != tc.wantRule {
+				t.Fatalf("Validate() rule = %s, want %s", violation.Rule, tc.wantRule)
+			}
+		})
+	}
+}
+
+func TestStoragePolicyValidateEmptyPolicyAllowsNothing(t *testing.T) {
+	var policy StoragePolicy
+	if err := policy.Validate(SourcePVC{Namespace: "vd-images", StorageClassName: "ceph-ssd-2-replica"}); err == nil {
+		t.Fatal("Validate() = nil, want the zero-value policy to reject everything")
+	}
+}
+
+func TestSourcePVCRefOf(t *testing.T) {
+	if _, ok := SourcePVCRefOf(&vsv1alpha.VirtualServer{}); ok {
+		t.Fatal("unconfigured root: SourcePVCRefOf() ok = true, want false")
+	}
+
+	pvcVS := &vsv1alpha.VirtualServer{}
+	pvcVS.Spec.Storage.Root = &vsv1alpha.VirtualServerStorageRoot{
+		PVC: &vsv1alpha.VirtualServerStorageRootPVCSource{
+			PVCName:          "ubuntu1804-docker-master-20210210-ord1",
+			PVCNamespace:     "vd-images",
+			StorageClassName: "ceph-ssd-2-replica",
+		},
+	}
+	ref, ok := SourcePVCRefOf(pvcVS)
+	if !ok || ref.Namespace != "vd-images" || ref.Name != "ubuntu1804-docker-master-20210210-ord1" || ref.StorageClassName != "ceph-ssd-2-replica" {
+		t.Fatalf("direct PVC source: SourcePVCRefOf() = %+v, %v, want vd-images/ubuntu1804-docker-master-20210210-ord1 on ceph-ssd-2-replica", ref, ok)
+	}
+
+	dvVS := &vsv1alpha.VirtualServer{}
+	dvVS.Spec.Storage.Root = &vsv1alpha.VirtualServerStorageRoot{
+		DataVolume: &vsv1alpha.VirtualServerStorageRootDataVolumeSource{
+			StorageClassName: "ceph-ssd-2-replica",
+			Source: vsv1alpha.DataVolumeSource{
+				PVC: &vsv1alpha.DataVolumeSourcePVC{
+					Name:      "ubuntu1804-docker-master-20210210-ord1",
+					Namespace: "vd-images",
+				},
+			},
+		},
+	}
+	ref, ok = SourcePVCRefOf(dvVS)
+	if !ok || ref.Namespace != "vd-images" || ref.Name != "ubuntu1804-docker-master-20210210-ord1" || ref.StorageClassName != "ceph-ssd-2-replica" {
+		t.Fatalf("DataVolume PVC source: SourcePVCRefOf() = %+v, %v, want vd-images/ubuntu1804-docker-master-20210210-ord1 on ceph-ssd-2-replica", ref, ok)
+	}
+
+	httpVS := &vsv1alpha.VirtualServer{}
+	httpVS.Spec.Storage.Root = &vsv1alpha.VirtualServerStorageRoot{
+		DataVolume: &vsv1alpha.VirtualServerStorageRootDataVolumeSource{
+			StorageClassName: "ceph-ssd-2-replica",
+			Source: vsv1alpha.DataVolumeSource{
+				HTTP: &vsv1alpha.DataVolumeSourceHTTP{URL: "https://images.example.com/ubuntu1804.qcow2"},
+			},
+		},
+	}
+	if _, ok := SourcePVCRefOf(httpVS); ok {
+		t.Fatal("DataVolume HTTP source: SourcePVCRefOf() ok = true, want false")
+	}
+}
+
+func TestContains(t *testing.T) {
+	if contains(nil, "a") {
+		t.Fatal("contains(nil, \"a\") = true, want false")
+	}
+	if !contains([]string{"a", "b"}, "b") {
+		t.Fatal("contains([a b], \"b\") = false, want true")
+	}
+	if contains([]string{"a", "b"}, "c") {
+		t.Fatal("contains([a b], \"c\") = true, want false")
+	}
+}
@@ -0,0 +1,189 @@
+// Package validation catches VirtualServer misconfigurations before they
+// are submitted to the API server, the way an admission webhook would.
+package validation
+
+import (
+	"context"
+	"fmt"
+
+	vsv1alpha "github.com/coreweave/virtual-server/api/v1alpha1"
+	"github.com/coreweave/virtual-server/pkg/policy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Validate runs all pre-flight checks against vs and returns every error
+// found, rather than stopping at the first one, so a caller can report the
+// full set of problems in one pass. Checks that need to confirm a
+// referenced object exists in the cluster (floating IP Services, and the
+// source PVC the root disk size is compared against) use c;
+// pass a nil client to skip those checks, e.g. when validating offline.
+func Validate(ctx context.Context, c client.Reader, vs *vsv1alpha.VirtualServer) []error {
+	var errs []error
+	for _, check := range []func(*vsv1alpha.VirtualServer) error{
+		checkGPUAndCPUTypeMutuallyExclusive,
+		checkMemoryQuantity,
+		checkCPUQuantity,
+		checkPortRanges,
+		checkPublicIPAndDirectAttach,
+		checkOSImageCompatibility,
+		checkCredentials,
+	} {
+		if err := check(vs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c != nil {
+		if err := checkFloatingIPServices(ctx, c, vs); err != nil {
+			errs = append(errs, err)
+		}
+		if err := checkRootDiskSize(ctx, c, vs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func checkGPUAndCPUTypeMutuallyExclusive(vs *vsv1alpha.VirtualServer) error {
+	if vs.Spec.Resources.GPU != nil && vs.Spec.Resources.GPU.Type != "" &&
+		vs.Spec.Resources.CPU != nil && vs.Spec.Resources.CPU.Type != "" {
+		return fmt.Errorf("GPU type %q and CPU type %q are mutually exclusive; CPU type is selected automatically when a GPU type is set",
+			vs.Spec.Resources.GPU.Type, vs.Spec.Resources.CPU.Type)
+	}
+	return nil
+}
+
+func checkMemoryQuantity(vs *vsv1alpha.VirtualServer) error {
+	if vs.Spec.Resources.Memory == "" {
+		return fmt.Errorf("memory is required")
+	}
+	if _, err := resource.ParseQuantity(vs.Spec.Resources.Memory); err != nil {
+		return fmt.Errorf("memory %q is not a valid quantity: %w", vs.Spec.Resources.Memory, err)
+	}
+	return nil
+}
+
+func checkCPUQuantity(vs *vsv1alpha.VirtualServer) error {
+	if vs.Spec.Resources.CPU == nil || vs.Spec.Resources.CPU.Count == 0 {
+		return fmt.Errorf("cpu count is required")
+	}
+	return nil
+}
+
+func checkPortRanges(vs *vsv1alpha.VirtualServer) error {
+	seenOn := map[int32]string{}
+	for _, port := range vs.Spec.Network.TCP {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("tcp port %d is out of range [1, 65535]", port)
+		}
+		seenOn[port] = "tcp"
+	}
+	for _, port := range vs.Spec.Network.UDP {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("udp port %d is out of range [1, 65535]", port)
+		}
+		if proto, ok := seenOn[port]; ok && proto == "tcp" {
+			return fmt.Errorf("port %d is exposed as both tcp and udp", port)
+		}
+	}
+	return nil
+}
+
+func checkPublicIPAndDirectAttach(vs *vsv1alpha.VirtualServer) error {
+	if vs.Spec.Network.DirectAttachLoadBalancerIP && !vs.Spec.Network.PublicIP {
+		return fmt.Errorf("directAttachLoadBalancerIP requires enablePublicIP to also be set")
+	}
+	return nil
+}
+
+// checkFloatingIPServices confirms every Service named in
+// vs.Spec.Network.FloatingIPs exists in vs's namespace and is of type
+// LoadBalancer.
+func checkFloatingIPServices(ctx context.Context, c client.Reader, vs *vsv1alpha.VirtualServer) error {
+	for _, name := range vs.Spec.Network.FloatingIPs {
+		svc := &corev1.Service{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: vs.Namespace, Name: name}, svc); err != nil {
+			return fmt.Errorf("floating IP service %q: %w", name, err)
+		}
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			return fmt.Errorf("floating IP service %q must be of type LoadBalancer, got %s", name, svc.Spec.Type)
+		}
+	}
+	return nil
+}
+
+// checkRootDiskSize confirms the requested root disk size is at least as
+// large as the live source PVC it clones, fetching that PVC by the
+// namespace/name policy.SourcePVCRefOf resolves from either
+// ConfigureStorageRootWithPVCSource or a DataVolume with a PVC source
+// (the same ref pkg/policy.ValidateVirtualServer checks) rather than
+// trusting a size recorded on the VirtualServer spec itself.
+func checkRootDiskSize(ctx context.Context, c client.Reader, vs *vsv1alpha.VirtualServer) error {
+	ref, ok := policy.SourcePVCRefOf(vs)
+	if !ok {
+		return nil
+	}
+	root := vs.Spec.Storage.Root
+	var size string
+	switch {
+	case root.PVC != nil:
+		size = root.PVC.Size
+	case root.DataVolume != nil:
+		size = root.DataVolume.Size
+	}
+	rootSize, err := resource.ParseQuantity(size)
+	if err != nil {
+		return fmt.Errorf("root disk size %q is not a valid quantity: %w", size, err)
+	}
+
+	sourcePVC := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if err := c.Get(ctx, key, sourcePVC); err != nil {
+		return fmt.Errorf("source pvc %s/%s: %w", key.Namespace, key.Name, err)
+	}
+	sourceSize := sourcePVC.Spec.Resources.Requests[corev1.ResourceStorage]
+
+	if rootSize.Cmp(sourceSize) < 0 {
+		return fmt.Errorf("root disk size %s is smaller than source PVC %s/%s size %s",
+			size, key.Namespace, key.Name, sourceSize.String())
+	}
+	return nil
+}
+
+func checkOSImageCompatibility(vs *vsv1alpha.VirtualServer) error {
+	isWindowsImage := isWindowsImageFamily(vs.Spec.OS.ImageFamily)
+	switch vs.Spec.OS.Type {
+	case vsv1alpha.VirtualServerOSTypeWindows:
+		if vs.Spec.OS.ImageFamily != "" && !isWindowsImage {
+			return fmt.Errorf("OS type Windows is not compatible with image family %q", vs.Spec.OS.ImageFamily)
+		}
+	case vsv1alpha.VirtualServerOSTypeLinux:
+		if isWindowsImage {
+			return fmt.Errorf("OS type Linux is not compatible with Windows image family %q", vs.Spec.OS.ImageFamily)
+		}
+	}
+	return nil
+}
+
+func isWindowsImageFamily(imageFamily string) bool {
+	return len(imageFamily) >= len("windows") && imageFamily[:len("windows")] == "windows"
+}
+
+// checkCredentials requires that every user configured on the
+// VirtualServer that sets only one of username and password provide an
+// SSH public key instead. A user with both, or with neither, passes. This
+// catches predicates like `if !usernameExist || passwordExist`, which
+// rejects a present password rather than a missing one.
+func checkCredentials(vs *vsv1alpha.VirtualServer) error {
+	for _, user := range vs.Spec.Users {
+		hasUsername := user.Username != ""
+		hasPassword := user.Password != ""
+		hasSSHKey := len(user.SSHPublicKeys) > 0
+		if hasUsername != hasPassword && !hasSSHKey {
+			return fmt.Errorf("user %q must set both username and password, or provide an SSH public key instead", user.Username)
+		}
+	}
+	return nil
+}
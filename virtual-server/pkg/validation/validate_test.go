@@ -0,0 +1,186 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	vsv1alpha "github.com/coreweave/virtual-server/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func validVirtualServer() *vsv1alpha.VirtualServer {
+	vs := &vsv1alpha.VirtualServer{}
+	vs.Spec.Resources.CPU = &vsv1alpha.VirtualServerCPU{Count: 2}
+	vs.Spec.Resources.Memory = "16Gi"
+	vs.Spec.OS.Type = vsv1alpha.VirtualServerOSTypeLinux
+	return vs
+}
+
+func TestCheckGPUAndCPUTypeMutuallyExclusive(t *testing.T) {
+	vs := validVirtualServer()
+	vs.Spec.Resources.GPU = &vsv1alpha.VirtualServerGPU{Type: "Quadro_RTX_4000"}
+	if err := checkGPUAndCPUTypeMutuallyExclusive(vs); err != nil {
+		t.Fatalf("GPU alone: checkGPUAndCPUTypeMutuallyExclusive() = %v, want nil", err)
+	}
+
+	vs.Spec.Resources.CPU.Type = "amd-epyc-rome"
+	if err := checkGPUAndCPUTypeMutuallyExclusive(vs); err == nil {
+		t.Fatal("GPU type and CPU type both set: checkGPUAndCPUTypeMutuallyExclusive() = nil, want error")
+	}
+}
+
+func TestCheckMemoryQuantity(t *testing.T) {
+	vs := validVirtualServer()
+	if err := checkMemoryQuantity(vs); err != nil {
+		t.Fatalf("valid memory: checkMemoryQuantity() = %v, want nil", err)
+	}
+
+	vs.Spec.Resources.Memory = "not-a-quantity"
+	if err := checkMemoryQuantity(vs); err == nil {
+		t.Fatal("invalid memory: checkMemoryQuantity() = nil, want error")
+	}
+
+	vs.Spec.Resources.Memory = ""
+	if err := checkMemoryQuantity(vs); err == nil {
+		t.Fatal("empty memory: checkMemoryQuantity() = nil, want error")
+	}
+}
+
+func TestCheckPortRanges(t *testing.T) {
+	vs := validVirtualServer()
+	vs.Spec.Network.TCP = []int32{22, 443}
+	vs.Spec.Network.UDP = []int32{4172}
+	if err := checkPortRanges(vs); err != nil {
+		t.Fatalf("disjoint valid ports: checkPortRanges() = %v, want nil", err)
+	}
+
+	vs.Spec.Network.TCP = []int32{70000}
+	if err := checkPortRanges(vs); err == nil {
+		t.Fatal("out-of-range tcp port: checkPortRanges() = nil, want error")
+	}
+
+	vs.Spec.Network.TCP = []int32{22}
+	vs.Spec.Network.UDP = []int32{22}
+	if err := checkPortRanges(vs); err == nil {
+		t.Fatal("same port on tcp and udp: checkPortRanges() = nil, want error")
+	}
+}
+
+func TestCheckPublicIPAndDirectAttach(t *testing.T) {
+	vs := validVirtualServer()
+	vs.Spec.Network.DirectAttachLoadBalancerIP = true
+	vs.Spec.Network.PublicIP = true
+	if err := checkPublicIPAndDirectAttach(vs); err != nil {
+		t.Fatalf("both set: checkPublicIPAndDirectAttach() = %v, want nil", err)
+	}
+
+	vs.Spec.Network.PublicIP = false
+	if err := checkPublicIPAndDirectAttach(vs); err == nil {
+		t.Fatal("direct attach without public IP: checkPublicIPAndDirectAttach() = nil, want error")
+	}
+}
+
+func TestCheckOSImageCompatibility(t *testing.T) {
+	vs := validVirtualServer()
+	vs.Spec.OS.Type = vsv1alpha.VirtualServerOSTypeWindows
+	vs.Spec.OS.ImageFamily = "windows-server-2022"
+	if err := checkOSImageCompatibility(vs); err != nil {
+		t.Fatalf("windows OS with windows image: checkOSImageCompatibility() = %v, want nil", err)
+	}
+
+	vs.Spec.OS.Type = vsv1alpha.VirtualServerOSTypeLinux
+	if err := checkOSImageCompatibility(vs); err == nil {
+		t.Fatal("linux OS with windows image: checkOSImageCompatibility() = nil, want error")
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	vs := validVirtualServer()
+	vs.Spec.Users = []vsv1alpha.VirtualServerUser{{Username: "core", Password: "hunter2"}}
+	if err := checkCredentials(vs); err != nil {
+		t.Fatalf("username and password: checkCredentials() = %v, want nil", err)
+	}
+
+	vs.Spec.Users = []vsv1alpha.VirtualServerUser{{SSHPublicKeys: []string{"ssh-ed25519 AAAA..."}}}
+	if err := checkCredentials(vs); err != nil {
+		t.Fatalf("ssh key only: checkCredentials() = %v, want nil", err)
+	}
+
+	vs.Spec.Users = []vsv1alpha.VirtualServerUser{{Username: "core"}}
+	if err := checkCredentials(vs); err == nil {
+		t.Fatal("username without password or ssh key: checkCredentials() = nil, want error")
+	}
+}
+
+func TestCheckRootDiskSize(t *testing.T) {
+	sourcePVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "ubuntu1804-docker-master-20210210-ord1", Namespace: "vd-images"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("40Gi")},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(sourcePVC).Build()
+
+	vs := validVirtualServer()
+	vs.Namespace = "default"
+	vs.Spec.Storage.Root = &vsv1alpha.VirtualServerStorageRoot{
+		PVC: &vsv1alpha.VirtualServerStorageRootPVCSource{
+			Size:         "40Gi",
+			PVCName:      sourcePVC.Name,
+			PVCNamespace: sourcePVC.Namespace,
+		},
+	}
+	if err := checkRootDiskSize(context.Background(), c, vs); err != nil {
+		t.Fatalf("root size equal to source: checkRootDiskSize() = %v, want nil", err)
+	}
+
+	vs.Spec.Storage.Root.PVC.Size = "10Gi"
+	if err := checkRootDiskSize(context.Background(), c, vs); err == nil {
+		t.Fatal("root size smaller than source: checkRootDiskSize() = nil, want error")
+	}
+
+	vs.Spec.Storage.Root.PVC.PVCName = "does-not-exist"
+	if err := checkRootDiskSize(context.Background(), c, vs); err == nil {
+		t.Fatal("missing source pvc: checkRootDiskSize() = nil, want error")
+	}
+}
+
+func TestCheckRootDiskSizeDataVolumePVCSource(t *testing.T) {
+	sourcePVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "ubuntu1804-docker-master-20210210-ord1", Namespace: "vd-images"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("40Gi")},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(sourcePVC).Build()
+
+	vs := validVirtualServer()
+	vs.Namespace = "default"
+	vs.Spec.Storage.Root = &vsv1alpha.VirtualServerStorageRoot{
+		DataVolume: &vsv1alpha.VirtualServerStorageRootDataVolumeSource{
+			Size:             "40Gi",
+			StorageClassName: "ceph-ssd-2-replica",
+			Source: vsv1alpha.DataVolumeSource{
+				PVC: &vsv1alpha.DataVolumeSourcePVC{
+					Name:      sourcePVC.Name,
+					Namespace: sourcePVC.Namespace,
+				},
+			},
+		},
+	}
+	if err := checkRootDiskSize(context.Background(), c, vs); err != nil {
+		t.Fatalf("root size equal to source: checkRootDiskSize() = %v, want nil", err)
+	}
+
+	vs.Spec.Storage.Root.DataVolume.Size = "10Gi"
+	if err := checkRootDiskSize(context.Background(), c, vs); err == nil {
+		t.Fatal("root size smaller than source: checkRootDiskSize() = nil, want error")
+	}
+}
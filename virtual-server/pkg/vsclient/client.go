@@ -0,0 +1,378 @@
+// Package vsclient wraps the day-to-day lifecycle operations of a
+// VirtualServer (create, apply, delete, start/stop, and wait-for-status)
+// behind a single Client, so callers don't have to open-code
+// controller-runtime and kubevirt.io/client-go calls the way the example in
+// examples/go/main.go historically did.
+package vsclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	vsv1alpha "github.com/coreweave/virtual-server/api/v1alpha1"
+	"github.com/coreweave/virtual-server/pkg/policy"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"kubevirt.io/client-go/kubecli"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultFieldManager = "vsclient"
+
+// Client wraps a controller-runtime client and a kubevirt client to provide
+// VirtualServer lifecycle operations as methods instead of free functions
+// operating on a shared struct.
+type Client struct {
+	client         client.WithWatch
+	kubevirtClient kubecli.KubevirtClient
+	fieldManager   string
+	storagePolicy  *policy.StoragePolicy
+}
+
+// New constructs a Client from an already-configured controller-runtime
+// client and kubevirt client. vsv1alpha must already be registered on c's
+// scheme. The client must support Watch (e.g. built with
+// client.NewWithWatch) since Watch/WaitReady/WaitStopped rely on it.
+func New(c client.WithWatch, kubevirtClient kubecli.KubevirtClient, opts ...Option) *Client {
+	vc := &Client{
+		client:         c,
+		kubevirtClient: kubevirtClient,
+		fieldManager:   defaultFieldManager,
+	}
+	for _, opt := range opts {
+		opt(vc)
+	}
+	return vc
+}
+
+// Create creates vs. The caller is responsible for filling in vs via the
+// vsv1alpha builder methods (SetRegion, SetOS, ConfigureStorageRoot*, etc.)
+// before calling Create. If the Client was built with WithStoragePolicy,
+// Create refuses to submit vs when its root disk's PVC source violates
+// that policy.
+func (c *Client) Create(ctx context.Context, vs *vsv1alpha.VirtualServer) error {
+	if err := c.enforceStoragePolicy(ctx, vs); err != nil {
+		return err
+	}
+	if err := c.client.Create(ctx, vs); err != nil {
+		return fmt.Errorf("create virtualserver %s/%s: %w", vs.Namespace, vs.Name, err)
+	}
+	return nil
+}
+
+// Apply performs a server-side apply of vs, creating it if it does not
+// exist or updating the fields owned by the configured field manager
+// (WithFieldManager) if it does. Like Create, it refuses to submit vs
+// when its root disk's PVC source violates a WithStoragePolicy policy.
+func (c *Client) Apply(ctx context.Context, vs *vsv1alpha.VirtualServer) error {
+	if err := c.enforceStoragePolicy(ctx, vs); err != nil {
+		return err
+	}
+	patch := client.Apply
+	if err := c.client.Patch(ctx, vs, patch, client.ForceOwnership, client.FieldOwner(c.fieldManager)); err != nil {
+		return fmt.Errorf("apply virtualserver %s/%s: %w", vs.Namespace, vs.Name, err)
+	}
+	return nil
+}
+
+// enforceStoragePolicy refuses vs if it configures a root disk PVC source
+// that violates c.storagePolicy. It is a no-op when no policy was
+// configured via WithStoragePolicy, or when vs has no PVC source to check
+// (neither ConfigureStorageRootWithPVCSource nor a
+// ConfigureStorageRootWithDataVolumeSource with a DataVolumeSourcePVC).
+func (c *Client) enforceStoragePolicy(ctx context.Context, vs *vsv1alpha.VirtualServer) error {
+	if c.storagePolicy == nil {
+		return nil
+	}
+	ref, ok := policy.SourcePVCRefOf(vs)
+	if !ok {
+		return nil
+	}
+
+	sourcePVC := &corev1.PersistentVolumeClaim{}
+	key := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if err := c.client.Get(ctx, key, sourcePVC); err != nil {
+		return fmt.Errorf("storage policy: look up source pvc %s/%s: %w", key.Namespace, key.Name, err)
+	}
+
+	if err := policy.ValidateVirtualServer(*c.storagePolicy, vs, sourcePVC); err != nil {
+		return fmt.Errorf("refusing to submit virtualserver %s/%s: %w", vs.Namespace, vs.Name, err)
+	}
+	return nil
+}
+
+// Delete deletes the named VirtualServer. It is not an error for the
+// VirtualServer to already be absent.
+func (c *Client) Delete(ctx context.Context, namespace, name string) error {
+	vs := &vsv1alpha.VirtualServer{}
+	vs.Namespace = namespace
+	vs.Name = name
+	if err := c.client.Delete(ctx, vs); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete virtualserver %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// Get fetches the named VirtualServer.
+func (c *Client) Get(ctx context.Context, namespace, name string) (*vsv1alpha.VirtualServer, error) {
+	vs := &vsv1alpha.VirtualServer{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, vs); err != nil {
+		return nil, fmt.Errorf("get virtualserver %s/%s: %w", namespace, name, err)
+	}
+	return vs, nil
+}
+
+// List returns all VirtualServers in namespace.
+func (c *Client) List(ctx context.Context, namespace string, opts ...client.ListOption) (*vsv1alpha.VirtualServerList, error) {
+	list := &vsv1alpha.VirtualServerList{}
+	opts = append([]client.ListOption{client.InNamespace(namespace)}, opts...)
+	if err := c.client.List(ctx, list, opts...); err != nil {
+		return nil, fmt.Errorf("list virtualservers in %s: %w", namespace, err)
+	}
+	return list, nil
+}
+
+// Start starts the underlying VirtualMachine for the named VirtualServer.
+func (c *Client) Start(namespace, name string) error {
+	if err := c.kubevirtClient.VirtualMachine(namespace).Start(name, &kubecli.StartOptions{}); err != nil {
+		return fmt.Errorf("start virtualserver %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// Stop stops the underlying VirtualMachine for the named VirtualServer.
+func (c *Client) Stop(namespace, name string) error {
+	if err := c.kubevirtClient.VirtualMachine(namespace).Stop(name); err != nil {
+		return fmt.Errorf("stop virtualserver %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// Restart restarts the underlying VirtualMachine for the named VirtualServer.
+func (c *Client) Restart(namespace, name string) error {
+	if err := c.kubevirtClient.VirtualMachine(namespace).Restart(name); err != nil {
+		return fmt.Errorf("restart virtualserver %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// StatusEvent is delivered on the channel returned by Watch whenever the
+// Ready condition of the watched VirtualServer transitions.
+type StatusEvent struct {
+	VirtualServer *vsv1alpha.VirtualServer
+	Status        ReadyStatus
+	Err           error
+}
+
+// ReadyStatus mirrors the Ready condition of a VirtualServer as a small
+// enum so callers can switch on it instead of inspecting conditions.
+type ReadyStatus string
+
+const (
+	StatusReady   ReadyStatus = "Ready"
+	StatusStopped ReadyStatus = "Stopped"
+	StatusUnknown ReadyStatus = "Unknown"
+)
+
+func readyStatus(vs *vsv1alpha.VirtualServer) ReadyStatus {
+	cond := vs.GetReadyStatus()
+	switch {
+	case cond == nil:
+		return StatusUnknown
+	case cond.Reason == string(vsv1alpha.VSConditionReasonReady) &&
+		cond.Type == string(vsv1alpha.VSConditionTypeReady) &&
+		cond.Status == "True":
+		return StatusReady
+	case cond.Reason == string(vsv1alpha.VSConditionReasonStopped) &&
+		cond.Type == string(vsv1alpha.VSConditionTypeReady) &&
+		cond.Status == "False":
+		return StatusStopped
+	default:
+		return StatusUnknown
+	}
+}
+
+// Watch returns a channel of StatusEvent describing Ready-condition
+// transitions for the named VirtualServer until ctx is cancelled, at which
+// point the channel is closed.
+func (c *Client) Watch(ctx context.Context, namespace, name string) (<-chan StatusEvent, error) {
+	w, err := c.client.Watch(ctx, &vsv1alpha.VirtualServerList{},
+		client.InNamespace(namespace),
+		client.MatchingFields{"metadata.name": name},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("watch virtualserver %s/%s: %w", namespace, name, err)
+	}
+
+	events := make(chan StatusEvent)
+	go func() {
+		defer close(events)
+		defer w.Stop()
+		var last ReadyStatus
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+				vs, ok := event.Object.(*vsv1alpha.VirtualServer)
+				if !ok {
+					continue
+				}
+				status := readyStatus(vs)
+				if status == last {
+					continue
+				}
+				last = status
+				select {
+				case events <- StatusEvent{VirtualServer: vs, Status: status}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// WaitReady blocks until the named VirtualServer's Ready condition becomes
+// True, ctx is cancelled, or the WithTimeout WaitOption elapses, whichever
+// comes first. It replaces the old busy-loop Ready helper that ignored
+// context cancellation and slept for 2 nanoseconds between polls.
+func (c *Client) WaitReady(ctx context.Context, namespace, name string, opts ...WaitOption) (*vsv1alpha.VirtualServer, error) {
+	return c.waitFor(ctx, namespace, name, StatusReady, opts...)
+}
+
+// WaitStopped blocks until the named VirtualServer's Ready condition
+// reflects Stopped, ctx is cancelled, or the WithTimeout WaitOption
+// elapses, whichever comes first.
+func (c *Client) WaitStopped(ctx context.Context, namespace, name string, opts ...WaitOption) (*vsv1alpha.VirtualServer, error) {
+	return c.waitFor(ctx, namespace, name, StatusStopped, opts...)
+}
+
+// waitFor watches the named VirtualServer for its Ready condition to reach
+// want, falling back to a Get poll on every pollInterval tick in case watch
+// events are dropped or coalesced. If establishing the watch itself fails
+// (e.g. a transient apiserver error), it retries with exponential backoff
+// instead of failing the wait outright.
+func (c *Client) waitFor(ctx context.Context, namespace, name string, want ReadyStatus, opts ...WaitOption) (*vsv1alpha.VirtualServer, error) {
+	o := defaultWaitOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	// An initial Get both serves as the first status check and surfaces
+	// NotFound immediately, without waiting for a watch to be established.
+	vs, err := c.Get(ctx, namespace, name)
+	if apierrors.IsNotFound(err) {
+		return nil, &Error{Reason: ReasonNotFound, Namespace: namespace, Name: name, Err: err}
+	}
+	if err == nil {
+		if vs, done, terminalErr := checkReadyStatus(vs, want, namespace, name); done {
+			return vs, terminalErr
+		}
+	}
+
+	backoff := o.pollInterval
+	const maxBackoff = 30 * time.Second
+
+	for {
+		w, err := c.client.Watch(ctx, &vsv1alpha.VirtualServerList{},
+			client.InNamespace(namespace),
+			client.MatchingFields{"metadata.name": name},
+		)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return nil, waitDoneErr(ctx, namespace, name)
+			case <-time.After(backoff):
+			}
+			if backoff < maxBackoff {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = o.pollInterval
+
+		vs, done, terminalErr, watchClosed := c.watchUntil(ctx, w, namespace, name, want, o.pollInterval)
+		w.Stop()
+		if done {
+			return vs, terminalErr
+		}
+		if !watchClosed {
+			// ctx was cancelled/timed out.
+			return nil, waitDoneErr(ctx, namespace, name)
+		}
+		// Watch closed (e.g. resource version too old): loop around and
+		// re-establish it.
+	}
+}
+
+// watchUntil consumes events from an already-established watch until the
+// VirtualServer reaches want, ctx is done, or the watch itself closes. It
+// also polls on every interval tick as a fallback in case a watch event is
+// missed. watchClosed is false when the caller should treat the return as
+// final (ctx done); true when the watch channel closed and the caller
+// should re-establish it.
+func (c *Client) watchUntil(ctx context.Context, w watch.Interface, namespace, name string, want ReadyStatus, interval time.Duration) (vs *vsv1alpha.VirtualServer, done bool, terminalErr error, watchClosed bool) {
+	fallback := time.NewTicker(interval)
+	defer fallback.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false, nil, false
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil, false, nil, true
+			}
+			candidate, ok := event.Object.(*vsv1alpha.VirtualServer)
+			if !ok {
+				continue
+			}
+			if vs, done, terminalErr := checkReadyStatus(candidate, want, namespace, name); done {
+				return vs, true, terminalErr, false
+			}
+		case <-fallback.C:
+			candidate, err := c.Get(ctx, namespace, name)
+			if err != nil {
+				continue
+			}
+			if vs, done, terminalErr := checkReadyStatus(candidate, want, namespace, name); done {
+				return vs, true, terminalErr, false
+			}
+		}
+	}
+}
+
+// checkReadyStatus reports whether vs has already reached a terminal
+// outcome for want: either want itself, or StatusStopped when want is
+// StatusReady (which can never transition to Ready without an explicit
+// Start).
+func checkReadyStatus(vs *vsv1alpha.VirtualServer, want ReadyStatus, namespace, name string) (result *vsv1alpha.VirtualServer, done bool, err error) {
+	status := readyStatus(vs)
+	if status == want {
+		return vs, true, nil
+	}
+	if status == StatusStopped && want == StatusReady {
+		return nil, true, &Error{Reason: ReasonTerminalFailure, Namespace: namespace, Name: name,
+			Err: fmt.Errorf("virtualserver is Stopped, will not become Ready without being started")}
+	}
+	return nil, false, nil
+}
+
+func waitDoneErr(ctx context.Context, namespace, name string) error {
+	return &Error{Reason: ReasonTimeout, Namespace: namespace, Name: name, Err: ctx.Err()}
+}
@@ -0,0 +1,61 @@
+package vsclient
+
+import "fmt"
+
+// ErrorReason classifies why a wait operation on a VirtualServer did not
+// succeed, so callers can distinguish "keep retrying" from "give up" cases
+// without string-matching errors.
+type ErrorReason string
+
+const (
+	// ReasonNotFound means the VirtualServer does not exist.
+	ReasonNotFound ErrorReason = "NotFound"
+	// ReasonTimeout means the context was cancelled or its deadline (or the
+	// WithTimeout WaitOption) elapsed before the VirtualServer reached the
+	// desired state.
+	ReasonTimeout ErrorReason = "Timeout"
+	// ReasonTerminalFailure means the VirtualServer reached a condition that
+	// cannot transition to the desired state without intervention.
+	ReasonTerminalFailure ErrorReason = "TerminalFailure"
+)
+
+// Error is returned by the Client's Wait* methods.
+type Error struct {
+	Reason    ErrorReason
+	Namespace string
+	Name      string
+	// Err is the underlying error, if any (e.g. the apiserver error that
+	// produced ReasonNotFound, or the context's error for ReasonTimeout).
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("virtualserver %s/%s: %s: %v", e.Namespace, e.Name, e.Reason, e.Err)
+	}
+	return fmt.Sprintf("virtualserver %s/%s: %s", e.Namespace, e.Name, e.Reason)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound reports whether err is a *Error with ReasonNotFound.
+func IsNotFound(err error) bool {
+	return reasonIs(err, ReasonNotFound)
+}
+
+// IsTimeout reports whether err is a *Error with ReasonTimeout.
+func IsTimeout(err error) bool {
+	return reasonIs(err, ReasonTimeout)
+}
+
+// IsTerminalFailure reports whether err is a *Error with ReasonTerminalFailure.
+func IsTerminalFailure(err error) bool {
+	return reasonIs(err, ReasonTerminalFailure)
+}
+
+func reasonIs(err error, reason ErrorReason) bool {
+	vsErr, ok := err.(*Error)
+	return ok && vsErr.Reason == reason
+}
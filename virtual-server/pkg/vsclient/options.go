@@ -0,0 +1,62 @@
+package vsclient
+
+import (
+	"time"
+
+	"github.com/coreweave/virtual-server/pkg/policy"
+)
+
+// Option configures a Client constructed with New.
+type Option func(*Client)
+
+// WithFieldManager sets the field manager used for Apply (server-side
+// apply) calls. Defaults to "vsclient".
+func WithFieldManager(manager string) Option {
+	return func(c *Client) {
+		c.fieldManager = manager
+	}
+}
+
+// WithStoragePolicy gates Create and Apply on p: a VirtualServer whose
+// root disk clones a PVC source violating p is refused before it is ever
+// submitted to the API server. Without this option, Create/Apply enforce
+// no storage policy.
+func WithStoragePolicy(p policy.StoragePolicy) Option {
+	return func(c *Client) {
+		c.storagePolicy = &p
+	}
+}
+
+// WaitOption configures a WaitReady or WaitStopped call.
+type WaitOption func(*waitOptions)
+
+type waitOptions struct {
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+func defaultWaitOptions() *waitOptions {
+	return &waitOptions{
+		timeout:      0, // no timeout beyond the caller's context
+		pollInterval: time.Second,
+	}
+}
+
+// WithTimeout bounds how long WaitReady/WaitStopped will block before
+// returning a *Error with ReasonTimeout, independent of the passed-in
+// context's own deadline.
+func WithTimeout(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.timeout = d
+	}
+}
+
+// WithPollInterval sets the interval between the Get polls used as a
+// fallback when watch events are not delivered, and the base delay for the
+// exponential backoff used when establishing the watch fails. Defaults to
+// one second.
+func WithPollInterval(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.pollInterval = d
+	}
+}
@@ -0,0 +1,337 @@
+// Package vscluster declares a group of related VirtualServers (for
+// example a GPU training cluster head node plus N workers) as a single
+// object built on top of pkg/vsclient, analogous to how minikube's
+// single-machine MachineConfig evolved into a list of per-cluster nodes.
+package vscluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	vsv1alpha "github.com/coreweave/virtual-server/api/v1alpha1"
+	"github.com/coreweave/virtual-server/pkg/vsclient"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NodeOverride customizes a single VirtualServer generated from a Spec's
+// Template. Index is the zero-based position of the node within the group
+// (e.g. "worker-0", "worker-1", ...).
+type NodeOverride struct {
+	Index          int
+	Hostname       string
+	GPUCount       int32
+	FloatingIPName string
+	PVCAttachments []PVCAttachment
+}
+
+// PVCAttachment is an additional PVC-backed filesystem to attach to a node,
+// mirroring VirtualServer.AddPVCFileSystem.
+type PVCAttachment struct {
+	Name     string
+	PVCName  string
+	ReadOnly bool
+}
+
+// Spec describes a group of VirtualServers that should be created,
+// monitored, and torn down together.
+type Spec struct {
+	// Name identifies the group. Node names are derived as "<Name>-<index>".
+	Name      string
+	Namespace string
+	// Template is cloned for every node before Overrides[i] is applied.
+	Template vsv1alpha.VirtualServer
+	// Count is the number of nodes to create. Overrides may refer to
+	// indices [0, Count).
+	Count int
+	// Overrides customizes individual nodes by index. Nodes without an
+	// entry in Overrides are created from Template unmodified (besides
+	// name/namespace).
+	Overrides map[int]NodeOverride
+	// MaxConcurrency bounds how many VirtualServers are created/deleted at
+	// once. Defaults to 8 when zero.
+	MaxConcurrency int
+}
+
+const defaultMaxConcurrency = 8
+
+func (s Spec) maxConcurrency() int {
+	if s.MaxConcurrency <= 0 {
+		return defaultMaxConcurrency
+	}
+	return s.MaxConcurrency
+}
+
+// NodeName returns the name a node at the given index will be created
+// with.
+func (s Spec) NodeName(index int) string {
+	return fmt.Sprintf("%s-%d", s.Name, index)
+}
+
+// Status is the aggregated state of a group returned by WaitReady.
+type Status struct {
+	Ready   []string
+	Pending []string
+	Failed  map[string]error
+}
+
+// Group manages the VirtualServers belonging to a single Spec.
+type Group struct {
+	client *vsclient.Client
+	spec   Spec
+}
+
+// New constructs a Group for the given Spec.
+func New(vsClient *vsclient.Client, spec Spec) *Group {
+	return &Group{client: vsClient, spec: spec}
+}
+
+func (g *Group) buildNode(index int) *vsv1alpha.VirtualServer {
+	node := g.spec.Template.DeepCopy()
+	node.Name = g.spec.NodeName(index)
+	node.Namespace = g.spec.Namespace
+
+	override, ok := g.spec.Overrides[index]
+	if !ok {
+		return node
+	}
+	if override.Hostname != "" {
+		node.SetHostname(override.Hostname)
+	}
+	if override.GPUCount > 0 {
+		node.SetGPUCount(override.GPUCount)
+	}
+	if override.FloatingIPName != "" {
+		node.AddFloatingIP(override.FloatingIPName)
+	}
+	for _, pvc := range override.PVCAttachments {
+		node.AddPVCFileSystem(pvc.Name, pvc.PVCName, pvc.ReadOnly)
+	}
+	return node
+}
+
+// forEachNode runs fn for every node index in the group with at most
+// spec.maxConcurrency() running concurrently, collecting the first error
+// per node.
+func (g *Group) forEachNode(ctx context.Context, fn func(ctx context.Context, index int) error) map[int]error {
+	sem := make(chan struct{}, g.spec.maxConcurrency())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[int]error)
+
+	for i := 0; i < g.spec.Count; i++ {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, i); err != nil {
+				mu.Lock()
+				errs[i] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// Create creates every node in the group in parallel, bounded by
+// spec.MaxConcurrency. It returns a map of node index to error for any
+// node that failed to create; other nodes are left running.
+func (g *Group) Create(ctx context.Context) map[int]error {
+	return g.forEachNode(ctx, func(ctx context.Context, index int) error {
+		return g.client.Create(ctx, g.buildNode(index))
+	})
+}
+
+// WaitReady waits for every node in the group to become ready, returning
+// an aggregated Status. A node that fails to become ready is recorded in
+// Status.Failed rather than aborting the wait for the remaining nodes.
+func (g *Group) WaitReady(ctx context.Context) Status {
+	status := Status{Failed: make(map[string]error)}
+	var mu sync.Mutex
+
+	errs := g.forEachNode(ctx, func(ctx context.Context, index int) error {
+		name := g.spec.NodeName(index)
+		_, err := g.client.WaitReady(ctx, g.spec.Namespace, name)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			status.Failed[name] = err
+		} else {
+			status.Ready = append(status.Ready, name)
+		}
+		return err
+	})
+	_ = errs // individual errors are already captured in status.Failed
+	return status
+}
+
+// ScaleUp grows the group to newCount nodes (newCount must be greater than
+// the group's current Count) and creates the additional nodes.
+func (g *Group) ScaleUp(ctx context.Context, newCount int) map[int]error {
+	if newCount <= g.spec.Count {
+		return map[int]error{-1: fmt.Errorf("vscluster: ScaleUp target %d is not greater than current count %d", newCount, g.spec.Count)}
+	}
+	oldCount := g.spec.Count
+	g.spec.Count = newCount
+
+	sem := make(chan struct{}, g.spec.maxConcurrency())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[int]error)
+	for i := oldCount; i < newCount; i++ {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := g.client.Create(ctx, g.buildNode(i)); err != nil {
+				mu.Lock()
+				errs[i] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// ScaleDown shrinks the group to newCount nodes, deleting the trailing
+// nodes (and their PVC/Service attachments are left for the caller's
+// Teardown/garbage collection since they may be shared). If any node
+// fails to delete, g.spec.Count is left unchanged rather than reduced, so
+// the undeleted node is not silently dropped from future WaitReady/
+// ScaleUp/ScaleDown/Teardown tracking; the caller can retry ScaleDown
+// once the returned errors are resolved.
+func (g *Group) ScaleDown(ctx context.Context, newCount int) map[int]error {
+	if newCount >= g.spec.Count {
+		return map[int]error{-1: fmt.Errorf("vscluster: ScaleDown target %d is not less than current count %d", newCount, g.spec.Count)}
+	}
+	oldCount := g.spec.Count
+
+	sem := make(chan struct{}, g.spec.maxConcurrency())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := make(map[int]error)
+	for i := newCount; i < oldCount; i++ {
+		i := i
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := g.client.Delete(ctx, g.spec.Namespace, g.spec.NodeName(i)); err != nil {
+				mu.Lock()
+				errs[i] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) == 0 {
+		g.spec.Count = newCount
+	}
+	return errs
+}
+
+// RollingReplace recreates every node in the group from the current
+// Template/Overrides (e.g. after the caller has updated g.spec.Template to
+// roll out a new image or resource request), N nodes at a time where N is
+// Spec.MaxConcurrency. It waits for each batch to become ready before
+// deleting and recreating the next batch, so a bad Template only ever
+// takes down up to MaxConcurrency nodes at once. A node whose replacement
+// fails to become ready is recorded in the returned map and the remaining
+// nodes in its batch still proceed, but later batches are not started.
+func (g *Group) RollingReplace(ctx context.Context) map[int]error {
+	errs := make(map[int]error)
+	batchSize := g.spec.maxConcurrency()
+
+	for start := 0; start < g.spec.Count; start += batchSize {
+		end := start + batchSize
+		if end > g.spec.Count {
+			end = g.spec.Count
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		batchFailed := false
+		for i := start; i < end; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				name := g.spec.NodeName(i)
+				if err := g.client.Delete(ctx, g.spec.Namespace, name); err != nil {
+					mu.Lock()
+					errs[i] = err
+					batchFailed = true
+					mu.Unlock()
+					return
+				}
+				if err := g.client.Create(ctx, g.buildNode(i)); err != nil {
+					mu.Lock()
+					errs[i] = err
+					batchFailed = true
+					mu.Unlock()
+					return
+				}
+				if _, err := g.client.WaitReady(ctx, g.spec.Namespace, name); err != nil {
+					mu.Lock()
+					errs[i] = err
+					batchFailed = true
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if batchFailed {
+			break
+		}
+	}
+	return errs
+}
+
+// AuxiliaryObjects names the PVCs and LoadBalancer Services created
+// alongside a group's VirtualServers, so Teardown can garbage-collect
+// them instead of leaking them the way main.go's example-pvc and
+// example-floating-ip-service used to on failure paths.
+type AuxiliaryObjects struct {
+	PVCNames     []string
+	ServiceNames []string
+}
+
+// Teardown deletes every VirtualServer in the group, then the auxiliary
+// PVCs and LoadBalancer Services named in aux. It always attempts to
+// remove the auxiliary objects even if a VirtualServer delete failed.
+// VirtualServer failures are returned keyed by node index in nodeErrs;
+// auxiliary object failures are returned as a flat slice in auxErrs since
+// they have no index to key by and more than one of either kind can fail.
+func (g *Group) Teardown(ctx context.Context, k8s client.Client, aux AuxiliaryObjects) (nodeErrs map[int]error, auxErrs []error) {
+	nodeErrs = g.forEachNode(ctx, func(ctx context.Context, index int) error {
+		return g.client.Delete(ctx, g.spec.Namespace, g.spec.NodeName(index))
+	})
+
+	for _, pvcName := range aux.PVCNames {
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvc.Name = pvcName
+		pvc.Namespace = g.spec.Namespace
+		if err := client.IgnoreNotFound(k8s.Delete(ctx, pvc)); err != nil {
+			auxErrs = append(auxErrs, fmt.Errorf("delete pvc %s: %w", pvcName, err))
+		}
+	}
+	for _, svcName := range aux.ServiceNames {
+		svc := &corev1.Service{}
+		svc.Name = svcName
+		svc.Namespace = g.spec.Namespace
+		if err := client.IgnoreNotFound(k8s.Delete(ctx, svc)); err != nil {
+			auxErrs = append(auxErrs, fmt.Errorf("delete service %s: %w", svcName, err))
+		}
+	}
+	return nodeErrs, auxErrs
+}
@@ -0,0 +1,42 @@
+package vscluster
+
+import (
+	"testing"
+
+	vsv1alpha "github.com/coreweave/virtual-server/api/v1alpha1"
+)
+
+func TestGroupBuildNode(t *testing.T) {
+	template := vsv1alpha.NewVirtualServer("worker", "")
+	spec := Spec{
+		Name:      "worker",
+		Namespace: "default",
+		Template:  *template,
+		Count:     2,
+		Overrides: map[int]NodeOverride{
+			1: {
+				Index:          1,
+				Hostname:       "worker-1-custom",
+				GPUCount:       2,
+				FloatingIPName: "worker-1-floating-ip",
+				PVCAttachments: []PVCAttachment{
+					{Name: "scratch", PVCName: "worker-1-scratch", ReadOnly: false},
+				},
+			},
+		},
+	}
+	g := New(nil, spec)
+
+	node0 := g.buildNode(0)
+	if node0.Name != "worker-0" || node0.Namespace != "default" {
+		t.Fatalf("unmodified node: got name=%q namespace=%q, want worker-0/default", node0.Name, node0.Namespace)
+	}
+
+	// buildNode exercises every NodeOverride field, including Hostname via
+	// VirtualServer.SetHostname, so a future rename/removal of that builder
+	// method fails this test instead of shipping silently.
+	node1 := g.buildNode(1)
+	if node1.Name != "worker-1" {
+		t.Fatalf("overridden node: got name=%q, want worker-1", node1.Name)
+	}
+}